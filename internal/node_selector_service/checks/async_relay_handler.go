@@ -1,48 +1,169 @@
 package checks
 
 import (
+	"context"
 	"pokt_gateway_server/internal/node_selector_service/models"
 	"pokt_gateway_server/pkg/pokt/pokt_v0"
 	relayer_models "pokt_gateway_server/pkg/pokt/pokt_v0/models"
+	"pokt_gateway_server/pkg/pool"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// defaultRelayTimeout bounds a single node's relay so a slow or hung node
+// can't wedge the whole quality-of-service check.
+const defaultRelayTimeout = time.Second * 30
+
+const defaultRelayDispatchPoolWorkers = 64
+const defaultRelayDispatchPoolQueueSize = 256
+
+// RelayDispatchPoolConfig sizes the shared worker pool that every
+// SendRelaysAsync call dispatches relays through, so operators can tune
+// overall relay concurrency instead of it being a hardcoded ceiling.
+type RelayDispatchPoolConfig struct {
+	Workers   int
+	QueueSize int
+}
+
+// DefaultRelayDispatchPoolConfig returns the pool size used until
+// ConfigureRelayDispatchPool is called.
+func DefaultRelayDispatchPoolConfig() RelayDispatchPoolConfig {
+	return RelayDispatchPoolConfig{
+		Workers:   defaultRelayDispatchPoolWorkers,
+		QueueSize: defaultRelayDispatchPoolQueueSize,
+	}
+}
+
+// relayDispatchPool caps the number of relays dispatched concurrently across
+// all SendRelaysAsync calls, instead of spawning a goroutine per node.
+var relayDispatchPool atomic.Pointer[pool.Pool]
+
+func init() {
+	cfg := DefaultRelayDispatchPoolConfig()
+	relayDispatchPool.Store(pool.New(cfg.Workers, cfg.QueueSize))
+}
+
+// ConfigureRelayDispatchPool replaces the shared relay dispatch pool with one
+// sized per cfg. Intended to be called once during startup config wiring,
+// before any relays are dispatched; the previous pool is drained and closed
+// in the background.
+func ConfigureRelayDispatchPool(cfg RelayDispatchPoolConfig) {
+	old := relayDispatchPool.Swap(pool.New(cfg.Workers, cfg.QueueSize))
+	if old != nil {
+		go old.Close()
+	}
+}
+
 type nodeRelayResponse struct {
-	Node  *models.QosNode
-	Relay *relayer_models.SendRelayResponse
-	Error error
+	Node      *models.QosNode
+	Relay     *relayer_models.SendRelayResponse
+	Error     error
+	Cancelled bool
 }
 
-func SendRelaysAsync(relayer pokt_v0.PocketRelayer, nodes []*models.QosNode, payload string, method string) chan *nodeRelayResponse {
-	// Define a channel to receive relay responses
+// SendRelaysAsync dispatches a relay to every node through a shared, bounded
+// worker pool and returns a channel of responses as soon as every node's
+// relay has been submitted (submission blocks only while the pool's queue is
+// full), so the caller (e.g. the node selector) can start consuming results
+// as they arrive instead of waiting for the slowest node. Every node yields
+// exactly one response; once ctx is cancelled, nodes still outstanding yield
+// a response with Cancelled set. The returned channel is closed after every
+// node's response has been sent.
+func SendRelaysAsync(ctx context.Context, relayer pokt_v0.PocketRelayer, nodes []*models.QosNode, payload string, method string) <-chan *nodeRelayResponse {
 	relayResponses := make(chan *nodeRelayResponse, len(nodes))
 	var wg sync.WaitGroup
 
-	// Define a function to handle sending relay requests concurrently
 	sendRelayAsync := func(node *models.QosNode) {
 		defer wg.Done()
-		relay, err := relayer.SendRelay(&relayer_models.SendRelayRequest{
-			Signer:             node.GetAppStakeSigner(),
-			Payload:            &relayer_models.Payload{Data: payload, Method: method},
-			Chain:              node.GetChain(),
-			SelectedNodePubKey: node.GetPublicKey(),
-			Session:            node.MorseSession,
-		})
-		relayResponses <- &nodeRelayResponse{
-			Node:  node,
-			Relay: relay,
-			Error: err,
+
+		relayCtx, cancel := context.WithTimeout(ctx, defaultRelayTimeout)
+		defer cancel()
+
+		resultCh := make(chan *nodeRelayResponse, 1)
+		go func() {
+			relay, err := relayer.SendRelay(relayCtx, &relayer_models.SendRelayRequest{
+				Signer:             node.GetAppStakeSigner(),
+				Payload:            &relayer_models.Payload{Data: payload, Method: method},
+				Chain:              node.GetChain(),
+				SelectedNodePubKey: node.GetPublicKey(),
+				Session:            node.MorseSession,
+			})
+			resultCh <- &nodeRelayResponse{Node: node, Relay: relay, Error: err}
+		}()
+
+		// Select on relayCtx.Done() (the caller's ctx plus the per-node
+		// defaultRelayTimeout) rather than relying on relayer.SendRelay to
+		// return promptly on its own: this abandons the result (and frees
+		// the pool worker that called sendRelayAsync) as soon as either
+		// fires, even if the underlying PocketRelayer/PocketService
+		// implementation doesn't itself honor ctx.
+		select {
+		case <-relayCtx.Done():
+			relayResponses <- &nodeRelayResponse{Node: node, Cancelled: true}
+		case response := <-resultCh:
+			relayResponses <- response
 		}
 	}
 
-	// Start a goroutine for each node to send relay requests concurrently
+	// Submit each node's relay to the shared worker pool rather than
+	// spawning a goroutine per node, so overall relay concurrency has a
+	// real ceiling even under session rollover or a burst of QoS probes.
 	for _, node := range nodes {
 		wg.Add(1)
-		go sendRelayAsync(node)
+		node := node
+		if err := relayDispatchPool.Load().Go(func() { sendRelayAsync(node) }); err != nil {
+			relayResponses <- &nodeRelayResponse{Node: node, Cancelled: true}
+			wg.Done()
+		}
 	}
 
-	wg.Wait()
-	close(relayResponses)
+	// Close the channel from a supervisor goroutine once every node has
+	// responded (or been abandoned) so callers can range over it.
+	go func() {
+		wg.Wait()
+		close(relayResponses)
+	}()
 
 	return relayResponses
 }
+
+// SendRelaysAsyncFirstSuccess dispatches relays to nodes exactly like
+// SendRelaysAsync, but returns as soon as k non-error responses have been
+// received, cancelling the remaining in-flight relays. This lets callers
+// implement hedged requests or quorum reads against multiple nodes without
+// waiting for the slowest one.
+func SendRelaysAsyncFirstSuccess(ctx context.Context, relayer pokt_v0.PocketRelayer, nodes []*models.QosNode, payload string, method string, k int) []*nodeRelayResponse {
+	relayCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	relayResponses := SendRelaysAsync(relayCtx, relayer, nodes, payload, method)
+
+	successes := make([]*nodeRelayResponse, 0, k)
+	for i := 0; i < len(nodes); i++ {
+		select {
+		case response, ok := <-relayResponses:
+			if !ok {
+				return successes
+			}
+			if response.Cancelled || response.Error != nil {
+				continue
+			}
+
+			successes = append(successes, response)
+			if len(successes) >= k {
+				// Returning immediately (instead of draining the rest of
+				// the channel) is what makes this "first success" rather
+				// than "wait for everyone, then keep the first k" — cancel
+				// tells the remaining in-flight relays to abandon their
+				// results.
+				cancel()
+				return successes
+			}
+		case <-ctx.Done():
+			return successes
+		}
+	}
+
+	return successes
+}
@@ -0,0 +1,91 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"pokt_gateway_server/internal/node_selector_service/models"
+	relayer_models "pokt_gateway_server/pkg/pokt/pokt_v0/models"
+	"testing"
+	"time"
+)
+
+// fakeRelayer lets tests control how long SendRelay takes and whether it
+// errors, without depending on a real Pocket node.
+type fakeRelayer struct {
+	delay func(node string) time.Duration
+	err   func(node string) error
+}
+
+func (f *fakeRelayer) SendRelay(ctx context.Context, req *relayer_models.SendRelayRequest) (*relayer_models.SendRelayResponse, error) {
+	node := req.SelectedNodePubKey
+	if f.delay != nil {
+		select {
+		case <-time.After(f.delay(node)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		if err := f.err(node); err != nil {
+			return nil, err
+		}
+	}
+	return &relayer_models.SendRelayResponse{}, nil
+}
+
+func newQosNode(pubKey string) *models.QosNode {
+	return &models.QosNode{PublicKey: pubKey}
+}
+
+// TestSendRelaysAsyncFirstSuccessReturnsEarly asserts that
+// SendRelaysAsyncFirstSuccess returns as soon as k successes have arrived,
+// instead of blocking until every node (including deliberately slow ones)
+// has responded.
+func TestSendRelaysAsyncFirstSuccessReturnsEarly(t *testing.T) {
+	nodes := []*models.QosNode{
+		newQosNode("fast-1"),
+		newQosNode("fast-2"),
+		newQosNode("slow"),
+	}
+
+	relayer := &fakeRelayer{
+		delay: func(node string) time.Duration {
+			if node == "slow" {
+				return time.Second * 5
+			}
+			return time.Millisecond
+		},
+	}
+
+	start := time.Now()
+	successes := SendRelaysAsyncFirstSuccess(context.Background(), relayer, nodes, "payload", "method", 2)
+	elapsed := time.Since(start)
+
+	if len(successes) != 2 {
+		t.Fatalf("got %d successes, want 2", len(successes))
+	}
+	if elapsed >= time.Second {
+		t.Fatalf("SendRelaysAsyncFirstSuccess took %v, expected to return before the slow node's 5s delay", elapsed)
+	}
+}
+
+func TestSendRelaysAsyncFirstSuccessSkipsErrors(t *testing.T) {
+	nodes := []*models.QosNode{
+		newQosNode("bad"),
+		newQosNode("good"),
+	}
+
+	relayer := &fakeRelayer{
+		err: func(node string) error {
+			if node == "bad" {
+				return errors.New("boom")
+			}
+			return nil
+		},
+	}
+
+	successes := SendRelaysAsyncFirstSuccess(context.Background(), relayer, nodes, "payload", "method", 1)
+	if len(successes) != 1 || successes[0].Node.GetPublicKey() != "good" {
+		t.Fatalf("successes = %+v, want exactly the 'good' node", successes)
+	}
+}
@@ -0,0 +1,75 @@
+package pokt_client_decorators
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffRecordFailureSequence(t *testing.T) {
+	b := newBackoff(time.Millisecond*500, time.Second*60, 0, 2, 0)
+
+	want := []time.Duration{
+		time.Millisecond * 500,
+		time.Second * 1,
+		time.Second * 2,
+		time.Second * 4,
+		time.Second * 8,
+		time.Second * 16,
+		time.Second * 32,
+		time.Second * 60, // capped at maxInterval
+	}
+
+	for i, w := range want {
+		b.recordFailure()
+		got := b.currentDelay.Sub(b.lastFailure)
+		if got != w {
+			t.Fatalf("failure #%d: delay = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestBackoffRecordSuccessResets(t *testing.T) {
+	b := newBackoff(time.Millisecond*500, time.Second*60, 0, 2, 0)
+
+	b.recordFailure()
+	b.recordFailure()
+	if !b.shouldBackoff() {
+		t.Fatalf("expected shouldBackoff to be true after consecutive failures")
+	}
+
+	b.recordSuccess()
+	if b.shouldBackoff() {
+		t.Fatalf("expected shouldBackoff to be false after recordSuccess")
+	}
+
+	b.recordFailure()
+	got := b.currentDelay.Sub(b.lastFailure)
+	if got != time.Millisecond*500 {
+		t.Fatalf("delay after reset = %v, want initialInterval", got)
+	}
+}
+
+// TestBackoffNeverPermanentlyLocksOut guards against a regression where
+// shouldBackoff() short-circuited to an unconditional true once
+// maxElapsedTime had passed since the first failure in a streak. Since
+// recordSuccess is only ever reached via a dispatch that shouldBackoff
+// allowed through, a permanent true would mean the client could never
+// recover from a long outage without a process restart.
+func TestBackoffNeverPermanentlyLocksOut(t *testing.T) {
+	b := newBackoff(time.Millisecond, time.Millisecond*5, time.Millisecond*10, 2, 0)
+
+	for i := 0; i < 5; i++ {
+		b.recordFailure()
+	}
+
+	// Simulate a failure streak far older than maxElapsedTime: the delay
+	// window (capped at maxInterval) must still be the only thing gating
+	// shouldBackoff, not the age of the streak.
+	b.firstFailure = time.Now().Add(-time.Hour)
+	b.lastFailure = time.Now().Add(-time.Hour)
+	b.currentDelay = b.lastFailure.Add(time.Millisecond * 5)
+
+	if b.shouldBackoff() {
+		t.Fatalf("expected shouldBackoff false once the maxInterval-capped delay window has elapsed, regardless of maxElapsedTime")
+	}
+}
@@ -1,90 +1,409 @@
 package pokt_client_decorators
 
 import (
+	"context"
 	"errors"
 	"github.com/jellydator/ttlcache/v3"
+	"golang.org/x/sync/singleflight"
+	"math"
+	"math/rand"
 	"os-gateway/pkg/pokt/pokt_v0"
 	"os-gateway/pkg/pokt/pokt_v0/models"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const backoffThreshold = time.Second * 5
 const sessionExpirationTtl = time.Minute * 75
 const maxConcurrentDispatch = 50
+const negativeCacheTtl = time.Second * 2
+const dispatchTimeout = time.Second * 30
+
+const defaultInitialInterval = time.Millisecond * 500
+const defaultMaxInterval = time.Second * 60
+const defaultMultiplier = 2.0
+const defaultJitter = 0.2
+const defaultMaxElapsedTime = time.Minute * 15
 
 var ErrRecentlyFailed = errors.New("dispatch recently failed, returning early")
 
+// backoff implements an exponential backoff with jitter, multiplying the
+// delay by multiplier on every consecutive failure (up to maxInterval) and
+// resetting to zero on the first success. It is safe for concurrent use.
+type backoff struct {
+	mu sync.Mutex
+
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	multiplier      float64
+	jitter          float64
+	maxElapsedTime  time.Duration
+
+	attempts     int
+	currentDelay time.Time
+	lastFailure  time.Time
+	firstFailure time.Time
+}
+
+func newBackoff(initialInterval, maxInterval, maxElapsedTime time.Duration, multiplier, jitter float64) *backoff {
+	return &backoff{
+		initialInterval: initialInterval,
+		maxInterval:     maxInterval,
+		multiplier:      multiplier,
+		jitter:          jitter,
+		maxElapsedTime:  maxElapsedTime,
+	}
+}
+
+// recordFailure advances the backoff state: attempts (the consecutive
+// failure count) is incremented, and the delay grows from initialInterval
+// by b.multiplier per failure (capped at maxInterval) and is jittered by
+// +/- b.jitter.
+func (b *backoff) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.lastFailure.IsZero() {
+		b.firstFailure = now
+	}
+	b.lastFailure = now
+	b.attempts++
+
+	nextDelay := b.nextDelayLocked()
+	b.currentDelay = now.Add(nextDelay)
+}
+
+// recordSuccess resets the backoff back to zero.
+func (b *backoff) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts = 0
+	b.lastFailure = time.Time{}
+	b.firstFailure = time.Time{}
+	b.currentDelay = time.Time{}
+}
+
+// nextDelayLocked must be called with b.mu held. The delay is a pure function
+// of b.attempts (the consecutive failure count), not of how much wall-clock
+// time has elapsed between failures.
+func (b *backoff) nextDelayLocked() time.Duration {
+	delay := float64(b.initialInterval) * math.Pow(b.multiplier, float64(b.attempts-1))
+	if delay > float64(b.maxInterval) {
+		delay = float64(b.maxInterval)
+	}
+
+	jittered := delay + delay*b.jitter*(2*rand.Float64()-1)
+	if jittered < 0 {
+		jittered = 0
+	}
+	return time.Duration(jittered)
+}
+
+// shouldBackoff reports whether a dispatch should be skipped because we are
+// still within the current delay window. The delay is already capped at
+// maxInterval (see nextDelayLocked), so this keeps probing upstream on a
+// regular cadence forever rather than ever blocking permanently — the only
+// way out of a failure streak is a success via a dispatch this allows
+// through, so this must not short-circuit to an unconditional true once
+// maxElapsedTime has passed, or the client could never recover without a
+// process restart.
+func (b *backoff) shouldBackoff() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lastFailure.IsZero() {
+		return false
+	}
+	return time.Now().Before(b.currentDelay)
+}
+
+// state returns the current delay and how long the backoff has been active,
+// for callers (e.g. health checks / metrics) that want to surface it.
+func (b *backoff) state() (delay time.Duration, since time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lastFailure.IsZero() {
+		return 0, 0
+	}
+	return b.currentDelay.Sub(b.lastFailure), time.Since(b.firstFailure)
+}
+
+// sessionDispatchMetrics holds the counters exposed through Metrics, tracking
+// how effective singleflight collapsing and the negative cache are at
+// shielding the upstream during session rollover stampedes.
+type sessionDispatchMetrics struct {
+	singleflightShared atomic.Uint64
+	negativeCacheHits  atomic.Uint64
+}
+
+// CachedClientMetrics is a point-in-time snapshot of sessionDispatchMetrics.
+type CachedClientMetrics struct {
+	SingleflightShared uint64
+	NegativeCacheHits  uint64
+	// InFlightByKey is the number of in-flight GetSession dispatches per
+	// AppPubKey+Chain cache key, so callers can tell which key is
+	// stampeding rather than just the total across all keys.
+	InFlightByKey map[string]int
+}
+
 type CachedClient struct {
 	pokt_v0.PocketService
-	lastFailure            time.Time
+	backoff                *backoff
 	concurrentDispatchPool chan struct{}
 	sessionCache           *ttlcache.Cache[string, *models.GetSessionResponse]
+
+	// negativeCache remembers the error of the most recent failed dispatch
+	// per key for a short TTL, so a caller retrying immediately after a
+	// failure gets that same error back at once instead of entering
+	// singleflight.
+	negativeCache *ttlcache.Cache[string, error]
+	sessionGroup  singleflight.Group
+	metrics       sessionDispatchMetrics
+
+	inFlightMu sync.Mutex
+	inFlight   map[string]int
+}
+
+// CachedClientOption configures optional backoff knobs on NewCachedClient.
+type CachedClientOption func(*CachedClient)
+
+// WithBackoffInitialInterval sets the delay applied after the first consecutive failure.
+func WithBackoffInitialInterval(d time.Duration) CachedClientOption {
+	return func(c *CachedClient) { c.backoff.initialInterval = d }
+}
+
+// WithBackoffMaxInterval caps the delay between retries regardless of how many
+// consecutive failures have occurred.
+func WithBackoffMaxInterval(d time.Duration) CachedClientOption {
+	return func(c *CachedClient) { c.backoff.maxInterval = d }
+}
+
+// WithBackoffMultiplier sets the factor the delay is multiplied by on each
+// consecutive failure.
+func WithBackoffMultiplier(m float64) CachedClientOption {
+	return func(c *CachedClient) { c.backoff.multiplier = m }
+}
+
+// WithBackoffJitter sets the +/- fraction of randomness applied to each delay.
+func WithBackoffJitter(j float64) CachedClientOption {
+	return func(c *CachedClient) { c.backoff.jitter = j }
 }
 
-func NewCachedClient(pocketService pokt_v0.PocketService) *CachedClient {
+// WithBackoffMaxElapsedTime sets the backoff's maxElapsedTime. The value is
+// stored only: state() does not read it, and it does not gate
+// shouldBackoff: the client always keeps probing upstream on the
+// maxInterval-capped delay, so a failure streak can never leave it
+// permanently unable to dispatch.
+func WithBackoffMaxElapsedTime(d time.Duration) CachedClientOption {
+	return func(c *CachedClient) { c.backoff.maxElapsedTime = d }
+}
+
+func NewCachedClient(pocketService pokt_v0.PocketService, opts ...CachedClientOption) *CachedClient {
 	sessionCache := ttlcache.New[string, *models.GetSessionResponse](
 		ttlcache.WithTTL[string, *models.GetSessionResponse](sessionExpirationTtl),
 	)
 	go sessionCache.Start()
 
-	return &CachedClient{
-		PocketService:          pocketService,
-		lastFailure:            time.Time{},
+	negativeCache := ttlcache.New[string, error](
+		ttlcache.WithTTL[string, error](negativeCacheTtl),
+	)
+	go negativeCache.Start()
+
+	c := &CachedClient{
+		PocketService: pocketService,
+		backoff: newBackoff(
+			defaultInitialInterval,
+			defaultMaxInterval,
+			defaultMaxElapsedTime,
+			defaultMultiplier,
+			defaultJitter,
+		),
 		sessionCache:           sessionCache,
+		negativeCache:          negativeCache,
 		concurrentDispatchPool: make(chan struct{}, maxConcurrentDispatch),
+		inFlight:               make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
-func (c *CachedClient) GetSession(req *models.GetSessionRequest) (*models.GetSessionResponse, error) {
+func (c *CachedClient) GetSession(ctx context.Context, req *models.GetSessionRequest) (*models.GetSessionResponse, error) {
 	cacheKey := getCacheKey(req)
 	cachedSession := c.sessionCache.Get(cacheKey)
 	if cachedSession != nil && cachedSession.Value() != nil {
 		return cachedSession.Value(), nil
 	}
 
+	// Negative cache: a dispatch for this key failed moments ago, fail fast
+	// without even entering singleflight.
+	if recentErr := c.negativeCache.Get(cacheKey); recentErr != nil {
+		c.metrics.negativeCacheHits.Add(1)
+		return nil, recentErr.Value()
+	}
+
 	// Backoff check
-	if c.shouldBackoff() {
+	if c.backoff.shouldBackoff() {
 		return nil, ErrRecentlyFailed
 	}
 
-	// Limits the number of concurrent calls going out to a node
-	// to prevent overloading the node during session rollover
-	c.concurrentDispatchPool <- struct{}{}
-	defer func() {
-		<-c.concurrentDispatchPool
+	c.trackInFlight(cacheKey, 1)
+	defer c.trackInFlight(cacheKey, -1)
+
+	// Collapse concurrent misses for the same key (e.g. during session
+	// rollover) into a single upstream dispatch shared by all callers. The
+	// dispatch is deliberately run against dispatchCtx — bounded by
+	// dispatchTimeout but independent of any individual caller's ctx —
+	// rather than whichever caller's ctx happens to be the singleflight
+	// "leader": otherwise one caller's short timeout would cancel the
+	// shared call, hand a spurious context error to every follower on the
+	// key, and poison the negative cache / backoff for all of them. Each
+	// caller instead races its own ctx against the shared result below.
+	type sfResult struct {
+		response *models.GetSessionResponse
+		err      error
+		shared   bool
+	}
+	resultCh := make(chan sfResult, 1)
+	go func() {
+		dispatchCtx, cancel := context.WithTimeout(context.Background(), dispatchTimeout)
+		defer cancel()
+
+		result, err, shared := c.sessionGroup.Do(cacheKey, func() (interface{}, error) {
+			// Limits the number of concurrent calls going out to a node
+			// to prevent overloading the node during session rollover
+			select {
+			case c.concurrentDispatchPool <- struct{}{}:
+			case <-dispatchCtx.Done():
+				return nil, dispatchCtx.Err()
+			}
+			defer func() {
+				<-c.concurrentDispatchPool
+			}()
+
+			// Call underlying provider
+			response, err := c.PocketService.GetSession(dispatchCtx, req)
+			if err != nil {
+				c.backoff.recordFailure()
+				c.negativeCache.Set(cacheKey, err, ttlcache.DefaultTTL)
+				return nil, err
+			}
+
+			c.sessionCache.Set(cacheKey, response, ttlcache.DefaultTTL)
+			c.backoff.recordSuccess()
+			return response, nil
+		})
+		if err != nil {
+			resultCh <- sfResult{err: err, shared: shared}
+			return
+		}
+		resultCh <- sfResult{response: result.(*models.GetSessionResponse), shared: shared}
 	}()
 
-	// Call underlying provider
-	response, err := c.PocketService.GetSession(req)
-	if err != nil {
-		c.lastFailure = time.Now()
-		return nil, err
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resultCh:
+		if res.shared {
+			c.metrics.singleflightShared.Add(1)
+		}
+		if res.err != nil {
+			return nil, res.err
+		}
+		return res.response, nil
+	}
+}
+
+func (c *CachedClient) trackInFlight(cacheKey string, delta int) {
+	c.inFlightMu.Lock()
+	defer c.inFlightMu.Unlock()
+
+	c.inFlight[cacheKey] += delta
+	if c.inFlight[cacheKey] <= 0 {
+		delete(c.inFlight, cacheKey)
 	}
+}
+
+// Metrics returns a point-in-time snapshot of singleflight-shared count,
+// negative-cache hits, and the number of dispatches currently in flight per
+// key.
+func (c *CachedClient) Metrics() CachedClientMetrics {
+	c.inFlightMu.Lock()
+	inFlightByKey := make(map[string]int, len(c.inFlight))
+	for key, n := range c.inFlight {
+		inFlightByKey[key] = n
+	}
+	c.inFlightMu.Unlock()
 
-	c.sessionCache.Set(cacheKey, response, ttlcache.DefaultTTL)
-	c.lastFailure = time.Time{} // Reset last failure since it succeeded
-	return response, nil
+	return CachedClientMetrics{
+		SingleflightShared: c.metrics.singleflightShared.Load(),
+		NegativeCacheHits:  c.metrics.negativeCacheHits.Load(),
+		InFlightByKey:      inFlightByKey,
+	}
 }
 
-func (r *CachedClient) SendRelay(req *models.SendRelayRequest) (*models.SendRelayResponse, error) {
+func (r *CachedClient) SendRelay(ctx context.Context, req *models.SendRelayRequest) (*models.SendRelayResponse, error) {
 
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	session, err := r.GetSession(&models.GetSessionRequest{AppPubKey: req.Signer.PublicKey, Chain: req.Chain})
+	session, err := r.GetSession(ctx, &models.GetSessionRequest{AppPubKey: req.Signer.PublicKey, Chain: req.Chain})
 	if err != nil {
 		return nil, err
 	}
 
 	req.Session = session.Session
-	return r.PocketService.SendRelay(req)
+	response, err := r.PocketService.SendRelay(ctx, req)
+	if err != nil && models.IsSessionStale(err) {
+		// The cached session is no longer valid upstream: evict it and
+		// re-dispatch once against a freshly fetched session rather than
+		// letting every caller keep using the poisoned entry until TTL.
+		r.InvalidateSession(req.Signer.PublicKey, req.Chain)
+
+		session, sessionErr := r.GetSession(ctx, &models.GetSessionRequest{AppPubKey: req.Signer.PublicKey, Chain: req.Chain})
+		if sessionErr != nil {
+			return nil, err
+		}
+
+		req.Session = session.Session
+		return r.PocketService.SendRelay(ctx, req)
+	}
+
+	return response, err
 }
 
-func (c *CachedClient) shouldBackoff() bool {
-	return !c.lastFailure.IsZero() && time.Since(c.lastFailure) < backoffThreshold
+// InvalidateSession evicts the cached session for appPubKey+chain, if any,
+// returning whether an entry was present. Callers should invoke this when
+// they learn a session has gone stale (e.g. a relay failed with a
+// session-mismatch error) so the next GetSession re-dispatches instead of
+// serving the poisoned entry until its TTL expires.
+func (c *CachedClient) InvalidateSession(appPubKey, chain string) bool {
+	cacheKey := getCacheKey(&models.GetSessionRequest{AppPubKey: appPubKey, Chain: chain})
+
+	existed := c.sessionCache.Has(cacheKey)
+	c.sessionCache.Delete(cacheKey)
+	return existed
+}
+
+// InvalidateAll evicts every cached session.
+func (c *CachedClient) InvalidateAll() {
+	c.sessionCache.DeleteAll()
+}
+
+// BackoffState returns the current backoff delay and how long the backoff has
+// been active, so callers such as node_selector_service can surface it in
+// metrics or health checks.
+func (c *CachedClient) BackoffState() (delay time.Duration, activeSince time.Duration) {
+	return c.backoff.state()
 }
 
 func getCacheKey(req *models.GetSessionRequest) string {
 	return req.AppPubKey + "-" + req.Chain
-}
\ No newline at end of file
+}
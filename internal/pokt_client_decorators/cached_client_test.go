@@ -0,0 +1,113 @@
+package pokt_client_decorators
+
+import (
+	"context"
+	"errors"
+	"os-gateway/pkg/pokt/pokt_v0/models"
+	"testing"
+)
+
+// fakePocketService lets tests script GetSession/SendRelay responses without
+// a real Pocket node.
+type fakePocketService struct {
+	getSessionCalls int
+	sendRelayCalls  int
+
+	getSession func(calls int) (*models.GetSessionResponse, error)
+	sendRelay  func(calls int) (*models.SendRelayResponse, error)
+}
+
+func (f *fakePocketService) GetSession(_ context.Context, _ *models.GetSessionRequest) (*models.GetSessionResponse, error) {
+	f.getSessionCalls++
+	return f.getSession(f.getSessionCalls)
+}
+
+func (f *fakePocketService) SendRelay(_ context.Context, _ *models.SendRelayRequest) (*models.SendRelayResponse, error) {
+	f.sendRelayCalls++
+	return f.sendRelay(f.sendRelayCalls)
+}
+
+func newTestRelayRequest() *models.SendRelayRequest {
+	return &models.SendRelayRequest{
+		Signer: &models.Signer{PublicKey: "pub-key"},
+		Chain:  "0001",
+	}
+}
+
+func TestSendRelayEvictsAndRetriesOnceOnStaleSession(t *testing.T) {
+	staleErr := &models.RelayError{Code: models.RelayErrorCodeSessionInvalid, Message: "invalid session"}
+
+	svc := &fakePocketService{
+		getSession: func(calls int) (*models.GetSessionResponse, error) {
+			return &models.GetSessionResponse{}, nil
+		},
+		sendRelay: func(calls int) (*models.SendRelayResponse, error) {
+			if calls == 1 {
+				return nil, staleErr
+			}
+			return &models.SendRelayResponse{}, nil
+		},
+	}
+
+	client := NewCachedClient(svc)
+	resp, err := client.SendRelay(context.Background(), newTestRelayRequest())
+	if err != nil {
+		t.Fatalf("SendRelay returned unexpected error: %v", err)
+	}
+	if resp == nil {
+		t.Fatalf("expected a non-nil response after the retry succeeds")
+	}
+	if svc.sendRelayCalls != 2 {
+		t.Fatalf("sendRelayCalls = %d, want exactly 2 (original + one retry)", svc.sendRelayCalls)
+	}
+	if svc.getSessionCalls != 2 {
+		t.Fatalf("getSessionCalls = %d, want exactly 2 (original fetch + re-fetch after eviction)", svc.getSessionCalls)
+	}
+}
+
+func TestSendRelayDoesNotRetryASecondStaleFailure(t *testing.T) {
+	staleErr := &models.RelayError{Code: models.RelayErrorCodeSessionInvalid, Message: "invalid session"}
+
+	svc := &fakePocketService{
+		getSession: func(calls int) (*models.GetSessionResponse, error) {
+			return &models.GetSessionResponse{}, nil
+		},
+		sendRelay: func(calls int) (*models.SendRelayResponse, error) {
+			return nil, staleErr
+		},
+	}
+
+	client := NewCachedClient(svc)
+	resp, err := client.SendRelay(context.Background(), newTestRelayRequest())
+	if !errors.Is(err, staleErr) {
+		t.Fatalf("expected the stale-session error to be returned after a single retry, got %v", err)
+	}
+	if resp != nil {
+		t.Fatalf("expected a nil response on failure, got %+v", resp)
+	}
+	if svc.sendRelayCalls != 2 {
+		t.Fatalf("sendRelayCalls = %d, want exactly 2 (no further retries after the retry also fails)", svc.sendRelayCalls)
+	}
+}
+
+func TestSendRelayPassesThroughNonStaleError(t *testing.T) {
+	nonStaleErr := errors.New("upstream unavailable")
+
+	svc := &fakePocketService{
+		getSession: func(calls int) (*models.GetSessionResponse, error) {
+			return &models.GetSessionResponse{}, nil
+		},
+		sendRelay: func(calls int) (*models.SendRelayResponse, error) {
+			return nil, nonStaleErr
+		},
+	}
+
+	client := NewCachedClient(svc)
+	_, err := client.SendRelay(context.Background(), newTestRelayRequest())
+	if !errors.Is(err, nonStaleErr) {
+		t.Fatalf("SendRelay error = %v, want %v", err, nonStaleErr)
+	}
+	if svc.sendRelayCalls != 1 {
+		t.Fatalf("sendRelayCalls = %d, want exactly 1 (non-stale errors must not trigger a retry)", svc.sendRelayCalls)
+	}
+}
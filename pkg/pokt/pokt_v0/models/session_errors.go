@@ -0,0 +1,43 @@
+package models
+
+import "errors"
+
+// RelayErrorCode identifies the class of error a Pocket node returned for a
+// relay, as distinct from transport-level failures (timeouts, dials, etc).
+type RelayErrorCode int
+
+const (
+	RelayErrorCodeUnknown RelayErrorCode = iota
+	RelayErrorCodeSessionInvalid
+	RelayErrorCodeSessionNotFound
+	RelayErrorCodeOutOfSync
+)
+
+// RelayError wraps an error code and message returned by a Pocket node in
+// response to a relay or session dispatch.
+type RelayError struct {
+	Code    RelayErrorCode
+	Message string
+}
+
+func (e *RelayError) Error() string {
+	return e.Message
+}
+
+// IsSessionStale reports whether err indicates the session used for a relay
+// is no longer valid upstream (invalid, not found, or out of sync with the
+// current block height), meaning the cached session should be evicted rather
+// than reused on the next dispatch.
+func IsSessionStale(err error) bool {
+	var relayErr *RelayError
+	if !errors.As(err, &relayErr) {
+		return false
+	}
+
+	switch relayErr.Code {
+	case RelayErrorCodeSessionInvalid, RelayErrorCodeSessionNotFound, RelayErrorCodeOutOfSync:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsSessionStale(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil error", nil, false},
+		{"non-RelayError", errors.New("boom"), false},
+		{"unknown code", &RelayError{Code: RelayErrorCodeUnknown, Message: "unknown"}, false},
+		{"session invalid", &RelayError{Code: RelayErrorCodeSessionInvalid, Message: "invalid"}, true},
+		{"session not found", &RelayError{Code: RelayErrorCodeSessionNotFound, Message: "not found"}, true},
+		{"out of sync", &RelayError{Code: RelayErrorCodeOutOfSync, Message: "out of sync"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSessionStale(tt.err); got != tt.want {
+				t.Fatalf("IsSessionStale(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSessionStaleWrappedError(t *testing.T) {
+	wrapped := errors.Join(errors.New("context"), &RelayError{Code: RelayErrorCodeSessionInvalid, Message: "invalid"})
+	if !IsSessionStale(wrapped) {
+		t.Fatalf("expected IsSessionStale to unwrap and find the RelayError")
+	}
+}
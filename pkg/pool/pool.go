@@ -0,0 +1,80 @@
+// Package pool provides a reusable, fixed-size worker-goroutine pool for
+// bounding fan-out concurrency (e.g. relay dispatch per node) instead of
+// spawning a goroutine per task.
+package pool
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPoolClosed is returned by Go once the pool has been closed.
+var ErrPoolClosed = errors.New("pool: Go called on a closed pool")
+
+// Pool is a fixed-size set of worker goroutines that consume func() tasks
+// from a shared queue. It is safe for concurrent use.
+type Pool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+}
+
+// New starts a Pool with the given number of workers and a task queue of
+// queueSize. Workers run until Close is called.
+func New(workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{
+		tasks: make(chan func(), queueSize),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		task()
+	}
+}
+
+// Go submits task to the pool, blocking until a worker can accept it if the
+// queue is full. It returns ErrPoolClosed if the pool has already been
+// closed instead of submitting the task.
+func (p *Pool) Go(task func()) error {
+	// Held for the duration of the send so Close cannot close p.tasks out
+	// from under us (which would panic a send on a closed channel).
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.tasks <- task
+	return nil
+}
+
+// Close stops accepting new tasks and waits for all queued and in-flight
+// tasks to finish. It is safe to call Close more than once.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.tasks)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+}
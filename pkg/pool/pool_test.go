@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolRunsSubmittedTasks(t *testing.T) {
+	p := New(4, 16)
+	defer p.Close()
+
+	var n atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		if err := p.Go(func() {
+			defer wg.Done()
+			n.Add(1)
+		}); err != nil {
+			t.Fatalf("Go returned unexpected error: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if got := n.Load(); got != 50 {
+		t.Fatalf("ran %d tasks, want 50", got)
+	}
+}
+
+func TestPoolGoAfterCloseReturnsError(t *testing.T) {
+	p := New(2, 4)
+	p.Close()
+
+	if err := p.Go(func() {}); err != ErrPoolClosed {
+		t.Fatalf("Go after Close = %v, want ErrPoolClosed", err)
+	}
+}
+
+// TestPoolCloseRaceWithGo exercises Close racing with concurrent Go callers:
+// every Go call must either run its task or return ErrPoolClosed, never
+// panic with a send on a closed channel.
+func TestPoolCloseRaceWithGo(t *testing.T) {
+	p := New(4, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Go(func() {})
+		}()
+	}
+
+	p.Close()
+	wg.Wait()
+}